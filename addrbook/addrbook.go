@@ -0,0 +1,160 @@
+// Package addrbook implements a persistent, on-disk record of nodes
+// discovered while crawling a Sia network, modeled on the Tendermint
+// address book. Entries accumulate metadata across runs so that crawling
+// can proceed incrementally instead of starting from scratch every time.
+package addrbook
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// Entry is a single address book record for one network address.
+type Entry struct {
+	Address          string    `json:"address"`
+	FirstSeen        time.Time `json:"firstSeen"`
+	LastSeen         time.Time `json:"lastSeen"`
+	LastHandshake    time.Time `json:"lastHandshake"`
+	ConsecutiveFails int       `json:"consecutiveFails"`
+	Source           string    `json:"source"`
+	Version          string    `json:"version"`
+}
+
+// Book is a thread-safe, persistent set of addrbook entries.
+type Book struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]*Entry
+}
+
+// New loads the address book at path, creating an empty one if the file
+// does not yet exist.
+func New(path string) (*Book, error) {
+	b := &Book{
+		path:    path,
+		entries: make(map[string]*Entry),
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return b, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []*Entry
+	if err := json.NewDecoder(f).Decode(&entries); err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		b.entries[e.Address] = e
+	}
+	return b, nil
+}
+
+// Add records addr as discovered via source, leaving any existing entry
+// untouched besides updating LastSeen. It returns the entry for addr.
+func (b *Book) Add(addr, source string) *Entry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	e, ok := b.entries[addr]
+	if !ok {
+		e = &Entry{
+			Address:   addr,
+			FirstSeen: now,
+			Source:    source,
+		}
+		b.entries[addr] = e
+	}
+	e.LastSeen = now
+	return e
+}
+
+// MarkSuccess records a successful handshake with addr, resetting its
+// failure count and storing the version it reported.
+func (b *Book) MarkSuccess(addr, version string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e, ok := b.entries[addr]
+	if !ok {
+		e = &Entry{Address: addr, FirstSeen: time.Now()}
+		b.entries[addr] = e
+	}
+	now := time.Now()
+	e.LastSeen = now
+	e.LastHandshake = now
+	e.Version = version
+	e.ConsecutiveFails = 0
+}
+
+// MarkFailure records a failed handshake attempt against addr.
+func (b *Book) MarkFailure(addr string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e, ok := b.entries[addr]
+	if !ok {
+		e = &Entry{Address: addr, FirstSeen: time.Now()}
+		b.entries[addr] = e
+	}
+	e.LastSeen = time.Now()
+	e.ConsecutiveFails++
+}
+
+// Entry returns the entry for addr, if one exists.
+func (b *Book) Entry(addr string) (Entry, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e, ok := b.entries[addr]
+	if !ok {
+		return Entry{}, false
+	}
+	return *e, true
+}
+
+// Addresses returns every address currently in the book.
+func (b *Book) Addresses() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	addrs := make([]string, 0, len(b.entries))
+	for addr := range b.entries {
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+// Len returns the number of entries in the book.
+func (b *Book) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.entries)
+}
+
+// Save writes the address book to its backing path as JSON.
+func (b *Book) Save() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entries := make([]*Entry, 0, len(b.entries))
+	for _, e := range b.entries {
+		entries = append(entries, e)
+	}
+
+	f, err := os.Create(b.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
@@ -0,0 +1,58 @@
+package addrbook
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestBookSaveReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "addrbook.json")
+
+	book, err := New(path)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	book.Add("1.1.1.1:9981", "seed")
+	book.MarkSuccess("1.1.1.1:9981", "1.2.0")
+	book.MarkFailure("2.2.2.2:9981")
+	book.MarkFailure("2.2.2.2:9981")
+
+	if err := book.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	reloaded, err := New(path)
+	if err != nil {
+		t.Fatalf("New() (reload) error = %v", err)
+	}
+	if got := reloaded.Len(); got != 2 {
+		t.Fatalf("Len() = %d, want 2", got)
+	}
+
+	good, ok := reloaded.Entry("1.1.1.1:9981")
+	if !ok {
+		t.Fatal("missing entry for 1.1.1.1:9981 after reload")
+	}
+	if good.Version != "1.2.0" || good.ConsecutiveFails != 0 {
+		t.Errorf("entry = %+v, want version 1.2.0 and 0 consecutive fails", good)
+	}
+
+	bad, ok := reloaded.Entry("2.2.2.2:9981")
+	if !ok {
+		t.Fatal("missing entry for 2.2.2.2:9981 after reload")
+	}
+	if bad.ConsecutiveFails != 2 {
+		t.Errorf("ConsecutiveFails = %d, want 2", bad.ConsecutiveFails)
+	}
+}
+
+func TestNewMissingFileIsEmpty(t *testing.T) {
+	book, err := New(filepath.Join(t.TempDir(), "does-not-exist.json"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if got := book.Len(); got != 0 {
+		t.Errorf("Len() = %d, want 0", got)
+	}
+}
@@ -0,0 +1,217 @@
+// Package bootnode turns nodecmp into a long-running Sia network
+// participant: it listens for incoming handshakes, answers them with a
+// configurable advertised version, and optionally serves ShareNodes
+// requests out of a persistent address book. This is the accepting
+// counterpart to the crawler's dialing, modeled on the Ethereum
+// bootnode command.
+package bootnode
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ChrisSchinnerl/nodecmp/addrbook"
+	"github.com/ChrisSchinnerl/nodecmp/siamux"
+)
+
+// sweepInterval controls how often stale entries are evicted from
+// Server.lastSeen. An entry is only needed for RateLimit's duration, so
+// sweeping on this cadence keeps the map from growing without bound over
+// the life of a long-running server.
+const sweepInterval = time.Minute
+
+// Config controls the behavior of a Server.
+type Config struct {
+	Addr          string         // address to listen on, e.g. ":9981"
+	Version       string         // version advertised to peers
+	MaxPeers      int            // maximum number of concurrently connected peers
+	RateLimit     time.Duration  // minimum interval between accepted connections from the same IP
+	Book          *addrbook.Book // optional; if set, ShareNodes requests are answered from it
+	HandshakeWait time.Duration  // how long to wait for a peer's handshake
+}
+
+// DefaultConfig returns sane defaults for a Server.
+func DefaultConfig() Config {
+	return Config{
+		Addr:          ":9981",
+		Version:       "1.2.0",
+		MaxPeers:      256,
+		RateLimit:     time.Second,
+		HandshakeWait: 10 * time.Second,
+	}
+}
+
+// Server is a listening Sia bootnode.
+type Server struct {
+	cfg Config
+	sem chan struct{}
+
+	mu       sync.Mutex
+	lastSeen map[string]time.Time
+}
+
+// New creates a Server from cfg.
+func New(cfg Config) *Server {
+	if cfg.MaxPeers <= 0 {
+		cfg.MaxPeers = DefaultConfig().MaxPeers
+	}
+	return &Server{
+		cfg:      cfg,
+		sem:      make(chan struct{}, cfg.MaxPeers),
+		lastSeen: make(map[string]time.Time),
+	}
+}
+
+// ListenAndServe binds cfg.Addr and accepts connections until ctx is
+// canceled, at which point it stops accepting and waits for in-flight
+// connections to finish.
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	lc := net.ListenConfig{}
+	ln, err := lc.Listen(ctx, "tcp", s.cfg.Addr)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+	go s.sweepLoop(ctx)
+
+	var wg sync.WaitGroup
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			wg.Wait()
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		if !s.allow(conn.RemoteAddr()) {
+			conn.Close()
+			continue
+		}
+
+		select {
+		case s.sem <- struct{}{}:
+		default:
+			conn.Close()
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-s.sem }()
+			s.handle(conn)
+		}()
+	}
+}
+
+// allow reports whether addr may open a new connection, enforcing a
+// simple per-IP rate limit.
+func (s *Server) allow(addr net.Addr) bool {
+	ip := addr.String()
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if last, ok := s.lastSeen[ip]; ok && now.Sub(last) < s.cfg.RateLimit {
+		return false
+	}
+	s.lastSeen[ip] = now
+	return true
+}
+
+// sweepLoop periodically evicts stale lastSeen entries until ctx is
+// canceled, bounding the map's size for a server that runs indefinitely.
+func (s *Server) sweepLoop(ctx context.Context) {
+	t := time.NewTicker(sweepInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			s.sweep()
+		}
+	}
+}
+
+// sweep removes lastSeen entries that are already older than RateLimit,
+// since such an entry can no longer affect the outcome of allow.
+func (s *Server) sweep() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := time.Now().Add(-s.cfg.RateLimit)
+	for ip, last := range s.lastSeen {
+		if last.Before(cutoff) {
+			delete(s.lastSeen, ip)
+		}
+	}
+}
+
+// handle services a single accepted connection: it performs the
+// handshake, optionally answers a ShareNodes request, and logs the
+// outcome.
+func (s *Server) handle(netConn net.Conn) {
+	start := time.Now()
+	defer netConn.Close()
+
+	remote := netConn.RemoteAddr().String()
+	netConn.SetDeadline(start.Add(s.cfg.HandshakeWait))
+
+	conn := siamux.NewConn(netConn)
+	info, err := conn.ServeHandshake(s.cfg.Version, s.cfg.Addr)
+	if err != nil {
+		slog.Info("bootnode: handshake failed", "remote", remote, "duration", time.Since(start), "error", err)
+		return
+	}
+
+	// netConn.RemoteAddr() is the inbound connection's ephemeral source
+	// port, not an address anyone could dial back. Only record (and
+	// later re-share) the peer if it self-reported the address it
+	// actually listens on.
+	if s.cfg.Book != nil && info.ListenAddr != "" {
+		s.cfg.Book.Add(info.ListenAddr, "inbound")
+		s.cfg.Book.MarkSuccess(info.ListenAddr, info.Version)
+	}
+
+	if err := s.serveShareNodes(conn); err != nil && !strings.Contains(err.Error(), "EOF") {
+		slog.Info("bootnode: sharenodes failed", "remote", remote, "version", info.Version, "duration", time.Since(start), "error", err)
+		return
+	}
+
+	slog.Info("bootnode: served peer", "remote", remote, "version", info.Version, "duration", time.Since(start))
+}
+
+// serveShareNodes answers a single ShareNodes request, if the peer
+// sends one, from the server's address book.
+func (s *Server) serveShareNodes(conn *siamux.Conn) error {
+	if err := conn.ReadShareNodesRequest(); err != nil {
+		return err
+	}
+
+	var addrs []string
+	if s.cfg.Book != nil {
+		addrs = s.cfg.Book.Addresses()
+	}
+	return conn.WriteShareNodesResponse(addrs)
+}
+
+// String describes the server's listen address, for logging.
+func (s *Server) String() string {
+	return fmt.Sprintf("bootnode(%s)", s.cfg.Addr)
+}
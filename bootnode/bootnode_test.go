@@ -0,0 +1,101 @@
+package bootnode
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ChrisSchinnerl/nodecmp/addrbook"
+	"github.com/ChrisSchinnerl/nodecmp/siamux"
+)
+
+func TestAllowRateLimits(t *testing.T) {
+	s := New(Config{RateLimit: 50 * time.Millisecond})
+	addr := &net.TCPAddr{IP: net.ParseIP("1.2.3.4"), Port: 1111}
+
+	if !s.allow(addr) {
+		t.Fatal("allow() = false on first connection, want true")
+	}
+	if s.allow(addr) {
+		t.Fatal("allow() = true immediately after, want false (rate limited)")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if !s.allow(addr) {
+		t.Fatal("allow() = false once RateLimit has elapsed, want true")
+	}
+}
+
+func TestSweepEvictsStaleEntries(t *testing.T) {
+	s := New(Config{RateLimit: time.Millisecond})
+	addr := &net.TCPAddr{IP: net.ParseIP("1.2.3.4"), Port: 1111}
+	s.allow(addr)
+
+	time.Sleep(5 * time.Millisecond)
+	s.sweep()
+
+	s.mu.Lock()
+	_, ok := s.lastSeen["1.2.3.4"]
+	s.mu.Unlock()
+	if ok {
+		t.Error("sweep() left a stale entry in lastSeen")
+	}
+}
+
+func TestHandleServesHandshakeAndShareNodes(t *testing.T) {
+	book, err := addrbook.New(filepath.Join(t.TempDir(), "addrbook.json"))
+	if err != nil {
+		t.Fatalf("addrbook.New() error = %v", err)
+	}
+	book.Add("9.9.9.9:9981", "seed")
+
+	cfg := DefaultConfig()
+	cfg.Book = book
+	s := New(cfg)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		s.handle(c)
+	}()
+
+	conn, err := siamux.Dial(ln.Addr().String(), 2*time.Second)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+
+	info, err := conn.Handshake("1.2.0", "3.3.3.3:9981")
+	if err != nil {
+		t.Fatalf("Handshake() error = %v", err)
+	}
+	if info.Version != cfg.Version {
+		t.Errorf("Version = %q, want %q", info.Version, cfg.Version)
+	}
+
+	// The handshake itself recorded the dialer's self-reported listen
+	// address, so by the time ShareNodes is served it's in the book
+	// alongside the pre-seeded address.
+	addrs, err := conn.RequestShareNodes()
+	if err != nil {
+		t.Fatalf("RequestShareNodes() error = %v", err)
+	}
+	want := map[string]bool{"9.9.9.9:9981": true, "3.3.3.3:9981": true}
+	if len(addrs) != len(want) {
+		t.Fatalf("ShareNodes addrs = %v, want %v", addrs, want)
+	}
+	for _, a := range addrs {
+		if !want[a] {
+			t.Errorf("unexpected address %v in ShareNodes response", a)
+		}
+	}
+}
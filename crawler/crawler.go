@@ -0,0 +1,205 @@
+// Package crawler implements a breadth-first peer-discovery crawl of a
+// Sia network. Starting from a set of seed addresses, it performs the
+// version handshake against each reachable peer, asks it to share the
+// addresses it knows about via ShareNodes, and walks the resulting graph
+// until no new addresses are discovered. Results are persisted through
+// an addrbook.Book so that crawls can resume incrementally.
+package crawler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ChrisSchinnerl/nodecmp/addrbook"
+)
+
+// maxFailures is how many consecutive handshake failures an address may
+// accumulate before the crawler stops retrying it.
+const maxFailures = 5
+
+// Config controls the behavior of a Crawler.
+type Config struct {
+	Workers     int           // number of concurrent dial workers
+	DialTimeout time.Duration // per-dial timeout
+	Backoff     time.Duration // base backoff between retries of a failing address
+}
+
+// DefaultConfig returns sane defaults for a Crawler.
+func DefaultConfig() Config {
+	return Config{
+		Workers:     32,
+		DialTimeout: 10 * time.Second,
+		Backoff:     time.Second,
+	}
+}
+
+// Crawler walks a Sia network's peer graph, recording what it finds in
+// an address book.
+type Crawler struct {
+	book *addrbook.Book
+	cfg  Config
+
+	mu      sync.Mutex
+	visited map[string]bool
+}
+
+// New creates a Crawler that persists discovered peers to book.
+func New(book *addrbook.Book, cfg Config) *Crawler {
+	return &Crawler{
+		book:    book,
+		cfg:     cfg,
+		visited: make(map[string]bool),
+	}
+}
+
+// Run crawls the network starting from seeds, blocking until the frontier
+// is exhausted or ctx is canceled. It is safe to call Run again with new
+// seeds once it returns.
+func (c *Crawler) Run(ctx context.Context, seeds []string) error {
+	frontier := newQueue()
+
+	var pending sync.WaitGroup
+	for _, s := range seeds {
+		if c.enqueue(frontier, s, "seed") {
+			pending.Add(1)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		pending.Wait()
+		close(done)
+	}()
+
+	// Close the frontier once the crawl is exhausted or ctx is canceled,
+	// which wakes any worker blocked waiting for the next address.
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		frontier.close()
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < c.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				addr, ok := frontier.pop()
+				if !ok {
+					return
+				}
+				discovered := c.visit(ctx, addr)
+				for _, d := range discovered {
+					if c.enqueue(frontier, d, addr) {
+						pending.Add(1)
+					}
+				}
+				pending.Done()
+			}
+		}()
+	}
+
+	wg.Wait()
+	return ctx.Err()
+}
+
+// enqueue marks addr as queued (if not already visited) and pushes it to
+// frontier, reporting whether it was newly added. Because visited already
+// bounds the number of addresses the crawler will ever act on, the queue
+// behind frontier is unbounded rather than capped, so a peer reporting a
+// huge ShareNodes response queues addresses in memory instead of forcing
+// one goroutine per address the way a full fixed-size channel would.
+func (c *Crawler) enqueue(frontier *queue, addr, source string) bool {
+	c.mu.Lock()
+	if c.visited[addr] {
+		c.mu.Unlock()
+		return false
+	}
+	c.visited[addr] = true
+	c.mu.Unlock()
+
+	c.book.Add(addr, source)
+	frontier.push(addr)
+	return true
+}
+
+// visit performs a handshake and ShareNodes request against addr,
+// updating the address book with the outcome, and returns any newly
+// reported addresses. Both network calls are bounded by c.cfg.DialTimeout
+// and by ctx: canceling ctx closes the connection immediately rather than
+// leaving the worker blocked inside a read until the deadline fires.
+func (c *Crawler) visit(ctx context.Context, addr string) []string {
+	if entry, ok := c.book.Entry(addr); ok && entry.ConsecutiveFails > 0 {
+		if entry.ConsecutiveFails >= maxFailures {
+			return nil
+		}
+		time.Sleep(c.cfg.Backoff * time.Duration(entry.ConsecutiveFails))
+	}
+
+	conn, version, err := handshake(ctx, addr, c.cfg.DialTimeout)
+	if err != nil {
+		c.book.MarkFailure(addr)
+		return nil
+	}
+	defer conn.Close()
+	c.book.MarkSuccess(addr, version)
+
+	addrs, err := shareNodes(ctx, conn, c.cfg.DialTimeout)
+	if err != nil {
+		return nil
+	}
+	return addrs
+}
+
+// queue is an unbounded, synchronized FIFO of addresses. It replaces a
+// fixed-size channel as the crawl frontier so that a burst of newly
+// discovered addresses queues in memory rather than overflowing into an
+// unbounded number of goroutines.
+type queue struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	items  []string
+	closed bool
+}
+
+// newQueue returns an empty queue.
+func newQueue() *queue {
+	q := &queue{}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push appends addr to the queue and wakes one waiting pop, if any.
+func (q *queue) push(addr string) {
+	q.mu.Lock()
+	q.items = append(q.items, addr)
+	q.mu.Unlock()
+	q.cond.Signal()
+}
+
+// pop removes and returns the oldest queued address, blocking until one
+// is available or the queue is closed, in which case ok is false.
+func (q *queue) pop() (addr string, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.items) == 0 && !q.closed {
+		q.cond.Wait()
+	}
+	if len(q.items) == 0 {
+		return "", false
+	}
+	addr, q.items = q.items[0], q.items[1:]
+	return addr, true
+}
+
+// close marks the queue closed and wakes every blocked pop.
+func (q *queue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	q.cond.Broadcast()
+}
@@ -0,0 +1,116 @@
+package crawler
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ChrisSchinnerl/nodecmp/addrbook"
+	"github.com/ChrisSchinnerl/nodecmp/siamux"
+)
+
+func newTestBook(t *testing.T) *addrbook.Book {
+	t.Helper()
+	book, err := addrbook.New(filepath.Join(t.TempDir(), "addrbook.json"))
+	if err != nil {
+		t.Fatalf("addrbook.New() error = %v", err)
+	}
+	return book
+}
+
+// fakePeer starts a listener that performs a version handshake and
+// answers ShareNodes with addrs, so the BFS walk can be exercised
+// without a real Sia node.
+func fakePeer(t *testing.T, addrs []string) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				conn := siamux.NewConn(c)
+				if _, err := conn.ServeHandshake(ourVersion, ""); err != nil {
+					return
+				}
+				if err := conn.ReadShareNodesRequest(); err != nil {
+					return
+				}
+				conn.WriteShareNodesResponse(addrs)
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestCrawlerBFSDiscoversTransitivePeers(t *testing.T) {
+	leaf := fakePeer(t, nil)
+	mid := fakePeer(t, []string{leaf})
+	root := fakePeer(t, []string{mid})
+
+	book := newTestBook(t)
+	c := New(book, Config{Workers: 4, DialTimeout: 2 * time.Second, Backoff: time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.Run(ctx, []string{root}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	for _, addr := range []string{root, mid, leaf} {
+		entry, ok := book.Entry(addr)
+		if !ok {
+			t.Errorf("book missing entry for %v", addr)
+			continue
+		}
+		if entry.Version != ourVersion {
+			t.Errorf("entry for %v version = %q, want %q", addr, entry.Version, ourVersion)
+		}
+	}
+}
+
+func TestCrawlerRunReturnsPromptlyOnCancel(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			// Accept but never speak, simulating a peer that wedges
+			// the handshake read indefinitely.
+			if _, err := ln.Accept(); err != nil {
+				return
+			}
+		}
+	}()
+
+	book := newTestBook(t)
+	c := New(book, Config{Workers: 1, DialTimeout: 20 * time.Second, Backoff: time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- c.Run(ctx, []string{ln.Addr().String()}) }()
+
+	time.Sleep(50 * time.Millisecond) // let the worker start dialing
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("Run() error = %v, want context.Canceled", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run() did not return promptly after ctx was canceled")
+	}
+}
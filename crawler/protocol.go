@@ -0,0 +1,59 @@
+package crawler
+
+import (
+	"context"
+	"time"
+
+	"github.com/ChrisSchinnerl/nodecmp/siamux"
+)
+
+// ourVersion is advertised to peers during the handshake.
+const ourVersion = "1.2.0"
+
+// handshake dials addr, performs the version exchange, and returns the
+// peer's reported version along with the open connection so it can be
+// reused for a follow-up ShareNodes request. Both the dial and the
+// handshake read/write are bounded by timeout; in addition, canceling
+// ctx closes the connection immediately rather than waiting for the
+// deadline, since a raw net.Conn.Read is not otherwise selectable.
+func handshake(ctx context.Context, addr string, timeout time.Duration) (*siamux.Conn, string, error) {
+	conn, err := siamux.Dial(addr, timeout)
+	if err != nil {
+		return nil, "", err
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+	defer watchCancel(ctx, conn)()
+
+	// The crawler never listens for inbound connections, so it has no
+	// address of its own worth reporting back.
+	info, err := conn.Handshake(ourVersion, "")
+	if err != nil {
+		conn.Close()
+		return nil, "", err
+	}
+	return conn, info.Version, nil
+}
+
+// shareNodes issues a ShareNodes request over an already-handshaken
+// connection and returns the addresses the peer reports knowing about.
+// Like handshake, the call is bounded by both an I/O deadline and ctx.
+func shareNodes(ctx context.Context, conn *siamux.Conn, timeout time.Duration) ([]string, error) {
+	conn.SetDeadline(time.Now().Add(timeout))
+	defer watchCancel(ctx, conn)()
+	return conn.RequestShareNodes()
+}
+
+// watchCancel closes conn if ctx is canceled before the returned stop
+// function runs, unblocking any in-flight read or write on conn right
+// away instead of leaving it to wait out the I/O deadline.
+func watchCancel(ctx context.Context, conn *siamux.Conn) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+	return func() { close(done) }
+}
@@ -2,16 +2,36 @@ package main
 
 import (
 	"bufio"
-	"encoding/binary"
+	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
-	"io"
-	"net"
 	"os"
+	"os/signal"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
+
+	"github.com/ChrisSchinnerl/nodecmp/addrbook"
+	"github.com/ChrisSchinnerl/nodecmp/bootnode"
+	"github.com/ChrisSchinnerl/nodecmp/crawler"
+	"github.com/ChrisSchinnerl/nodecmp/nodeset"
+	"github.com/ChrisSchinnerl/nodecmp/report"
+	"github.com/ChrisSchinnerl/nodecmp/siamux"
 )
 
+// defaultConcurrency is how many nodes are probed at once when the
+// caller doesn't specify -concurrency.
+const defaultConcurrency = 64
+
+// defaultAddrBookPath is where the shared address book is persisted when
+// the caller doesn't specify one explicitly.
+const defaultAddrBookPath = "addrbook.json"
+
+// ourVersion is the version nodecmp advertises during a handshake.
+const ourVersion = "1.2.0"
+
 // some errors that might occur
 var (
 	errInvalidArgs = "provided args are invalid: "             // Indicates invalid user input
@@ -28,74 +48,32 @@ type nodeEntry struct {
 
 // printUsage prints the usage of the nodecmp tool
 func printUsage() {
-	fmt.Print("Usage: nodecmp [path1] [path2] ... [pathN]")
-}
-
-// readPrefix reads an object's prefix
-func readPrefix(r io.Reader) (uint64, error) {
-	prefix := make([]byte, 8)
-	if _, err := r.Read(prefix); err != nil {
-		return 0, err
-	}
-	return binary.LittleEndian.Uint64(prefix), nil
-}
-
-// writePrefix writes an object's prefix
-func writePrefix(w io.Writer, length uint64) error {
-	prefix := make([]byte, 8)
-	binary.LittleEndian.PutUint64(prefix, length)
-	if _, err := w.Write(prefix); err != nil {
-		return err
-	}
-	return nil
+	fmt.Print("Usage: nodecmp [-op intersect|union|diff|symdiff|quorum:K] [-outbound or|first|last]\n" +
+		"               [-format text|json|csv|prom] [-concurrency N] [path1] [path2] ... [pathN]\n" +
+		"       nodecmp crawl [-book path] [-workers N] [-from file1,file2,...] [seed1] [seed2] ... [seedN]\n" +
+		"       nodecmp serve [-addr addr] [-version v] [-maxpeers N] [-book path]\n")
 }
 
-// nodeVersion gets the version of a node by pinging it
+// nodeVersion gets the version of a node by performing a siamux
+// handshake with it.
 func nodeVersion(addr string) (string, error) {
-	// Create dialer
-	dialer := &net.Dialer{
-		Timeout: time.Minute,
-	}
-
-	// Connect to host
-	conn, err := dialer.Dial("tcp", addr)
-	if err != nil {
-		return "", err
-	}
-
-	// Send message prefix. 8 bytes version prefix + 5 bytes version
-	if err := writePrefix(conn, uint64(13)); err != nil {
-		return "", err
-	}
-
-	// Send own version prefix
-	ownVersion := []byte("1.2.0")
-	if err := writePrefix(conn, uint64(len(ownVersion))); err != nil {
-		return "", err
-	}
-
-	// Send own version
-	if _, err := conn.Write(ownVersion); err != nil {
-		return "", err
-	}
-
-	// Receive peer version prefix
-	prefix, err := readPrefix(conn)
+	conn, err := siamux.Dial(addr, time.Minute)
 	if err != nil {
 		return "", err
 	}
+	defer conn.Close()
 
-	// Receive peer version
-	version := make([]byte, prefix)
-	_, err = conn.Read(version)
+	info, err := conn.Handshake(ourVersion, "")
 	if err != nil {
 		return "", err
 	}
-	return string(version), nil
+	return info.Version, nil
 }
 
-// loadNodes reads a nodes file and returns the entries
-func loadNodes(path string) map[string]bool {
+// loadNodes reads a nodes file, seeds book with every address it
+// contains (so the compare-intersection workflow and the crawler share
+// the same node database), and returns the entries as a set.
+func loadNodes(path string, book *addrbook.Book) map[string]bool {
 	// Read file
 	f, err := os.Open(path)
 	if err != nil {
@@ -129,47 +107,214 @@ func loadNodes(path string) map[string]bool {
 	entrySet := make(map[string]bool)
 	for _, entry := range entries {
 		entrySet[entry.Address] = entry.Outbound
+		book.Add(entry.Address, path)
 	}
 	return entrySet
 }
 
-// intersect intersects 2 maps
-func intersect(m1 map[string]bool, m2 map[string]bool) map[string]bool {
-	intersected := make(map[string]bool)
-	for key, value := range m1 {
-		if _, exists := m2[key]; exists {
-			intersected[key] = value
+// parseOutboundMode parses the -outbound flag value into a
+// nodeset.OutboundMode.
+func parseOutboundMode(s string) (nodeset.OutboundMode, error) {
+	switch s {
+	case "", "or":
+		return nodeset.OutboundOR, nil
+	case "first":
+		return nodeset.OutboundFirst, nil
+	case "last":
+		return nodeset.OutboundLast, nil
+	default:
+		return 0, fmt.Errorf("unknown -outbound mode %q", s)
+	}
+}
+
+// runCrawl drives a crawl subcommand invocation: it loads (or creates) the
+// address book at bookPath, seeds it with the given addresses plus the
+// addresses intersected from fromPaths (the same workflow main's
+// compare mode uses), crawls the network reachable from them, and
+// persists the result.
+func runCrawl(bookPath string, workers int, seeds, fromPaths []string) error {
+	book, err := addrbook.New(bookPath)
+	if err != nil {
+		return err
+	}
+
+	if len(fromPaths) > 0 {
+		inputs := make([]nodeset.Input, len(fromPaths))
+		for i, path := range fromPaths {
+			inputs[i] = nodeset.Input{Path: path, Entries: loadNodes(path, book)}
+		}
+		result, err := nodeset.Apply("intersect", inputs, nodeset.OutboundOR)
+		if err != nil {
+			return err
 		}
+		for addr := range result {
+			seeds = append(seeds, addr)
+		}
+	}
+	if len(seeds) == 0 {
+		return fmt.Errorf("crawl: no seed addresses given (pass them as args or via -from)")
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	cfg := crawler.DefaultConfig()
+	if workers > 0 {
+		cfg.Workers = workers
+	}
+	c := crawler.New(book, cfg)
+	if err := c.Run(ctx, seeds); err != nil && ctx.Err() == nil {
+		return err
+	}
+
+	fmt.Printf("crawl complete: %v addresses known\n", book.Len())
+	return book.Save()
+}
+
+// runServe drives a serve subcommand invocation: it loads (or creates)
+// the address book at bookPath and runs a bootnode server until
+// interrupted.
+func runServe(bookPath, addr, version string, maxPeers int) error {
+	book, err := addrbook.New(bookPath)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	cfg := bootnode.DefaultConfig()
+	cfg.Addr = addr
+	cfg.Version = version
+	cfg.MaxPeers = maxPeers
+	cfg.Book = book
+
+	server := bootnode.New(cfg)
+	if err := server.ListenAndServe(ctx); err != nil {
+		return err
 	}
-	return intersected
+	return book.Save()
 }
 
 func main() {
 	// Get commandline args
 	args := os.Args[1:]
 
-	// There should be 2 or more
-	if len(args) < 2 {
+	if len(args) > 0 && args[0] == "crawl" {
+		fs := flag.NewFlagSet("crawl", flag.ExitOnError)
+		bookPath := fs.String("book", defaultAddrBookPath, "path to the persistent address book")
+		workers := fs.Int("workers", 0, "number of concurrent crawl workers (0 = default)")
+		from := fs.String("from", "", "comma-separated node files to seed from (intersected, as in the compare workflow)")
+		fs.Parse(args[1:])
+
+		var fromPaths []string
+		if *from != "" {
+			fromPaths = strings.Split(*from, ",")
+		}
+		if err := runCrawl(*bookPath, *workers, fs.Args(), fromPaths); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(args) > 0 && args[0] == "serve" {
+		fs := flag.NewFlagSet("serve", flag.ExitOnError)
+		bookPath := fs.String("book", defaultAddrBookPath, "path to the persistent address book")
+		addr := fs.String("addr", bootnode.DefaultConfig().Addr, "address to listen on")
+		version := fs.String("version", ourVersion, "version to advertise to peers")
+		maxPeers := fs.Int("maxpeers", bootnode.DefaultConfig().MaxPeers, "maximum number of concurrent peers")
+		fs.Parse(args[1:])
+
+		if err := runServe(*bookPath, *addr, *version, *maxPeers); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fs := flag.NewFlagSet("nodecmp", flag.ExitOnError)
+	op := fs.String("op", "intersect", "set operation: intersect, union, diff, symdiff, or quorum:K")
+	outbound := fs.String("outbound", "or", "how to merge the outbound bit across sources: or, first, or last")
+	format := fs.String("format", "text", "output format: text, json, csv, or prom")
+	concurrency := fs.Int("concurrency", defaultConcurrency, "number of nodes to probe at once")
+	fs.Parse(args)
+
+	// There should be 2 or more paths
+	paths := fs.Args()
+	if len(paths) < 2 {
 		printUsage()
 		return
 	}
 
-	// Pairwise intersect all entries
-	entryMap := loadNodes(args[0])
-	for _, path := range args[1:] {
-		entryMap = intersect(entryMap, loadNodes(path))
+	mode, err := parseOutboundMode(*outbound)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	book, err := addrbook.New(defaultAddrBookPath)
+	if err != nil {
+		fmt.Print(errInvalidMD, err)
+		os.Exit(1)
+	}
+
+	inputs := make([]nodeset.Input, len(paths))
+	for i, path := range paths {
+		inputs[i] = nodeset.Input{Path: path, Entries: loadNodes(path, book)}
+	}
+	if err := book.Save(); err != nil {
+		fmt.Print(errInvalidMD, err)
+		os.Exit(1)
+	}
+
+	result, err := nodeset.Apply(*op, inputs, mode)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	rep, err := report.New(*format, os.Stdout)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	probeAll(result, *concurrency, rep)
+	if err := rep.Flush(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+// probeAll probes every address in result for its version using a
+// bounded pool of concurrency workers, feeding each outcome to rep.
+func probeAll(result map[string]nodeset.Entry, concurrency int, rep report.Reporter) {
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+
+	addrs := make(chan string, len(result))
+	for address := range result {
+		addrs <- address
 	}
+	close(addrs)
 
 	var wg sync.WaitGroup
-	for address := range entryMap {
+	for i := 0; i < concurrency; i++ {
 		wg.Add(1)
-		go func(a string) {
-			version, err := nodeVersion(a)
-			if err == nil {
-				fmt.Printf("%v -> %v\n", a, version)
+		go func() {
+			defer wg.Done()
+			for addr := range addrs {
+				start := time.Now()
+				version, err := nodeVersion(addr)
+				rep.Record(report.Result{
+					Address: addr,
+					Version: version,
+					Err:     err,
+					Latency: time.Since(start).Seconds(),
+				})
 			}
-			wg.Done()
-		}(address)
+		}()
 	}
 	wg.Wait()
 }
@@ -0,0 +1,83 @@
+// Package nodeset generalizes the pairwise intersection nodecmp started
+// with into the full family of set operations across N node files:
+// union, difference, symmetric difference, and a "present in at least K
+// of N" quorum operator. Every operation preserves per-address
+// provenance (which input files contained the entry) alongside the
+// merged Outbound bit.
+package nodeset
+
+import "fmt"
+
+// OutboundMode controls how the Outbound bit is merged when an address
+// appears in more than one input.
+type OutboundMode int
+
+const (
+	// OutboundOR sets Outbound if any source reported it as true.
+	OutboundOR OutboundMode = iota
+	// OutboundFirst keeps the value from the first input that contains
+	// the address.
+	OutboundFirst
+	// OutboundLast keeps the value from the last input that contains
+	// the address.
+	OutboundLast
+)
+
+// Input is one node file's worth of entries, keyed by address.
+type Input struct {
+	Path    string
+	Entries map[string]bool // address -> wasoutboundpeer
+}
+
+// Entry is a single address in the result of a set operation, carrying
+// its merged Outbound bit and the provenance of every input it came
+// from.
+type Entry struct {
+	Outbound bool
+	Sources  []string
+}
+
+// merge folds the Outbound bit reported by in into e according to mode,
+// and records in as a source.
+func merge(e *Entry, in Input, outbound bool, mode OutboundMode) {
+	first := len(e.Sources) == 0
+	e.Sources = append(e.Sources, in.Path)
+
+	switch mode {
+	case OutboundFirst:
+		if first {
+			e.Outbound = outbound
+		}
+	case OutboundLast:
+		e.Outbound = outbound
+	default: // OutboundOR
+		e.Outbound = e.Outbound || outbound
+	}
+}
+
+// Apply parses op (one of "intersect", "union", "diff", "symdiff", or
+// "quorum:K") and runs it over inputs, merging the Outbound bit
+// according to mode.
+func Apply(op string, inputs []Input, mode OutboundMode) (map[string]Entry, error) {
+	switch {
+	case op == "intersect":
+		return Intersect(inputs, mode), nil
+	case op == "union":
+		return Union(inputs, mode), nil
+	case op == "diff":
+		if len(inputs) != 2 {
+			return nil, fmt.Errorf("nodeset: diff requires exactly 2 inputs, got %d", len(inputs))
+		}
+		return Difference(inputs[0], inputs[1], mode), nil
+	case op == "symdiff":
+		return SymmetricDifference(inputs, mode), nil
+	case len(op) > 7 && op[:7] == "quorum:":
+		var k int
+		if _, err := fmt.Sscanf(op[7:], "%d", &k); err != nil {
+			return nil, fmt.Errorf("nodeset: invalid quorum spec %q: %w", op, err)
+		}
+		return Quorum(inputs, k, mode), nil
+	default:
+		return nil, fmt.Errorf("nodeset: unknown op %q", op)
+	}
+}
@@ -0,0 +1,109 @@
+package nodeset
+
+// Intersect returns addresses present in every input.
+func Intersect(inputs []Input, mode OutboundMode) map[string]Entry {
+	result := make(map[string]Entry)
+	if len(inputs) == 0 {
+		return result
+	}
+
+	for addr, outbound := range inputs[0].Entries {
+		e := Entry{}
+		merge(&e, inputs[0], outbound, mode)
+
+		inAll := true
+		for _, in := range inputs[1:] {
+			outbound, ok := in.Entries[addr]
+			if !ok {
+				inAll = false
+				break
+			}
+			merge(&e, in, outbound, mode)
+		}
+		if inAll {
+			result[addr] = e
+		}
+	}
+	return result
+}
+
+// Union returns addresses present in any input.
+func Union(inputs []Input, mode OutboundMode) map[string]Entry {
+	result := make(map[string]Entry)
+	for _, in := range inputs {
+		for addr, outbound := range in.Entries {
+			e, ok := result[addr]
+			if !ok {
+				e = Entry{}
+			}
+			merge(&e, in, outbound, mode)
+			result[addr] = e
+		}
+	}
+	return result
+}
+
+// Difference returns addresses present in a but not in b.
+func Difference(a, b Input, mode OutboundMode) map[string]Entry {
+	result := make(map[string]Entry)
+	for addr, outbound := range a.Entries {
+		if _, ok := b.Entries[addr]; ok {
+			continue
+		}
+		e := Entry{}
+		merge(&e, a, outbound, mode)
+		result[addr] = e
+	}
+	return result
+}
+
+// SymmetricDifference returns addresses present in exactly one input.
+func SymmetricDifference(inputs []Input, mode OutboundMode) map[string]Entry {
+	counts := make(map[string]int)
+	for _, in := range inputs {
+		for addr := range in.Entries {
+			counts[addr]++
+		}
+	}
+
+	result := make(map[string]Entry)
+	for _, in := range inputs {
+		for addr, outbound := range in.Entries {
+			if counts[addr] != 1 {
+				continue
+			}
+			e := Entry{}
+			merge(&e, in, outbound, mode)
+			result[addr] = e
+		}
+	}
+	return result
+}
+
+// Quorum returns addresses present in at least k of the given inputs.
+// A k of zero or less yields an empty result.
+func Quorum(inputs []Input, k int, mode OutboundMode) map[string]Entry {
+	result := make(map[string]Entry)
+	if k <= 0 {
+		return result
+	}
+
+	entries := make(map[string]*Entry)
+	for _, in := range inputs {
+		for addr, outbound := range in.Entries {
+			e, ok := entries[addr]
+			if !ok {
+				e = &Entry{}
+				entries[addr] = e
+			}
+			merge(e, in, outbound, mode)
+		}
+	}
+
+	for addr, e := range entries {
+		if len(e.Sources) >= k {
+			result[addr] = *e
+		}
+	}
+	return result
+}
@@ -0,0 +1,186 @@
+package nodeset
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func addrs(m map[string]Entry) []string {
+	out := make([]string, 0, len(m))
+	for addr := range m {
+		out = append(out, addr)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestIntersect(t *testing.T) {
+	tests := []struct {
+		name   string
+		inputs []Input
+		want   []string
+	}{
+		{
+			name: "two overlapping inputs",
+			inputs: []Input{
+				{Path: "a", Entries: map[string]bool{"1.1.1.1:9981": true, "2.2.2.2:9981": false}},
+				{Path: "b", Entries: map[string]bool{"2.2.2.2:9981": true, "3.3.3.3:9981": false}},
+			},
+			want: []string{"2.2.2.2:9981"},
+		},
+		{
+			name: "no overlap",
+			inputs: []Input{
+				{Path: "a", Entries: map[string]bool{"1.1.1.1:9981": true}},
+				{Path: "b", Entries: map[string]bool{"2.2.2.2:9981": true}},
+			},
+			want: []string{},
+		},
+		{
+			name:   "no inputs",
+			inputs: nil,
+			want:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := addrs(Intersect(tt.inputs, OutboundOR))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Intersect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnion(t *testing.T) {
+	inputs := []Input{
+		{Path: "a", Entries: map[string]bool{"1.1.1.1:9981": true}},
+		{Path: "b", Entries: map[string]bool{"2.2.2.2:9981": false}},
+	}
+	want := []string{"1.1.1.1:9981", "2.2.2.2:9981"}
+
+	got := addrs(Union(inputs, OutboundOR))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Union() = %v, want %v", got, want)
+	}
+}
+
+func TestDifference(t *testing.T) {
+	a := Input{Path: "yesterday", Entries: map[string]bool{"1.1.1.1:9981": true, "2.2.2.2:9981": true}}
+	b := Input{Path: "today", Entries: map[string]bool{"2.2.2.2:9981": true}}
+
+	want := []string{"1.1.1.1:9981"}
+	got := addrs(Difference(a, b, OutboundOR))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Difference() = %v, want %v", got, want)
+	}
+}
+
+func TestSymmetricDifference(t *testing.T) {
+	inputs := []Input{
+		{Path: "a", Entries: map[string]bool{"1.1.1.1:9981": true, "2.2.2.2:9981": true}},
+		{Path: "b", Entries: map[string]bool{"2.2.2.2:9981": true, "3.3.3.3:9981": true}},
+	}
+	want := []string{"1.1.1.1:9981", "3.3.3.3:9981"}
+
+	got := addrs(SymmetricDifference(inputs, OutboundOR))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SymmetricDifference() = %v, want %v", got, want)
+	}
+}
+
+func TestQuorum(t *testing.T) {
+	inputs := []Input{
+		{Path: "a", Entries: map[string]bool{"1.1.1.1:9981": true}},
+		{Path: "b", Entries: map[string]bool{"1.1.1.1:9981": true, "2.2.2.2:9981": true}},
+		{Path: "c", Entries: map[string]bool{"1.1.1.1:9981": true, "2.2.2.2:9981": true, "3.3.3.3:9981": true}},
+	}
+
+	tests := []struct {
+		name string
+		k    int
+		want []string
+	}{
+		{name: "k=1", k: 1, want: []string{"1.1.1.1:9981", "2.2.2.2:9981", "3.3.3.3:9981"}},
+		{name: "k=2", k: 2, want: []string{"1.1.1.1:9981", "2.2.2.2:9981"}},
+		{name: "k=3", k: 3, want: []string{"1.1.1.1:9981"}},
+		{name: "k=0", k: 0, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := addrs(Quorum(inputs, tt.k, OutboundOR))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Quorum(k=%d) = %v, want %v", tt.k, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutboundModes(t *testing.T) {
+	inputs := []Input{
+		{Path: "a", Entries: map[string]bool{"1.1.1.1:9981": false}},
+		{Path: "b", Entries: map[string]bool{"1.1.1.1:9981": true}},
+	}
+
+	tests := []struct {
+		name string
+		mode OutboundMode
+		want bool
+	}{
+		{name: "or", mode: OutboundOR, want: true},
+		{name: "first", mode: OutboundFirst, want: false},
+		{name: "last", mode: OutboundLast, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Union(inputs, tt.mode)
+			got := result["1.1.1.1:9981"].Outbound
+			if got != tt.want {
+				t.Errorf("Outbound with mode %v = %v, want %v", tt.mode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	inputs := []Input{
+		{Path: "a", Entries: map[string]bool{"1.1.1.1:9981": true, "2.2.2.2:9981": true}},
+		{Path: "b", Entries: map[string]bool{"2.2.2.2:9981": true, "3.3.3.3:9981": true}},
+	}
+
+	tests := []struct {
+		name    string
+		op      string
+		want    []string
+		wantErr bool
+	}{
+		{name: "intersect", op: "intersect", want: []string{"2.2.2.2:9981"}},
+		{name: "union", op: "union", want: []string{"1.1.1.1:9981", "2.2.2.2:9981", "3.3.3.3:9981"}},
+		{name: "diff", op: "diff", want: []string{"1.1.1.1:9981"}},
+		{name: "symdiff", op: "symdiff", want: []string{"1.1.1.1:9981", "3.3.3.3:9981"}},
+		{name: "quorum:2", op: "quorum:2", want: []string{"2.2.2.2:9981"}},
+		{name: "unknown op", op: "bogus", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply(tt.op, inputs, OutboundOR)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Apply(%q) expected an error, got none", tt.op)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Apply(%q) returned unexpected error: %v", tt.op, err)
+			}
+			if !reflect.DeepEqual(addrs(got), tt.want) {
+				t.Errorf("Apply(%q) = %v, want %v", tt.op, addrs(got), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// csvReporter writes one row per Result, with a header row written on
+// construction.
+type csvReporter struct {
+	mu sync.Mutex
+	w  *csv.Writer
+}
+
+func newCSVReporter(w io.Writer) *csvReporter {
+	r := &csvReporter{w: csv.NewWriter(w)}
+	r.w.Write([]string{"address", "version", "reachable", "error", "latency_seconds"})
+	return r
+}
+
+func (r *csvReporter) Record(res Result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	errStr := ""
+	if res.Err != nil {
+		errStr = res.Err.Error()
+	}
+	r.w.Write([]string{
+		res.Address,
+		res.Version,
+		strconv.FormatBool(res.Err == nil),
+		errStr,
+		fmt.Sprintf("%f", res.Latency),
+	})
+}
+
+func (r *csvReporter) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.w.Flush()
+	return r.w.Error()
+}
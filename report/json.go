@@ -0,0 +1,47 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// jsonEntry is the newline-delimited JSON representation of a Result.
+type jsonEntry struct {
+	Address   string  `json:"address"`
+	Version   string  `json:"version,omitempty"`
+	Error     string  `json:"error,omitempty"`
+	Reachable bool    `json:"reachable"`
+	LatencyS  float64 `json:"latencySeconds"`
+}
+
+// jsonReporter writes one JSON object per line (ndjson).
+type jsonReporter struct {
+	mu  sync.Mutex
+	w   io.Writer
+	enc *json.Encoder
+}
+
+func (r *jsonReporter) Record(res Result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.enc == nil {
+		r.enc = json.NewEncoder(r.w)
+	}
+
+	entry := jsonEntry{
+		Address:   res.Address,
+		Version:   res.Version,
+		Reachable: res.Err == nil,
+		LatencyS:  res.Latency,
+	}
+	if res.Err != nil {
+		entry.Error = res.Err.Error()
+	}
+	r.enc.Encode(entry)
+}
+
+func (r *jsonReporter) Flush() error {
+	return nil
+}
@@ -0,0 +1,48 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// promReporter accumulates results and, on Flush, writes them out in
+// the Prometheus textfile-collector format: one metric per line, safe
+// to be picked up by node_exporter's textfile collector.
+type promReporter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	results []Result
+}
+
+func (r *promReporter) Record(res Result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results = append(r.results, res)
+}
+
+func (r *promReporter) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fmt.Fprintln(r.w, "# HELP siacoin_node_reachable Whether the node responded to a version handshake.")
+	fmt.Fprintln(r.w, "# TYPE siacoin_node_reachable gauge")
+	for _, res := range r.results {
+		reachable := 0
+		if res.Err == nil {
+			reachable = 1
+		}
+		fmt.Fprintf(r.w, "siacoin_node_reachable{addr=%q,version=%q} %d\n", res.Address, res.Version, reachable)
+	}
+
+	fmt.Fprintln(r.w, "# HELP siacoin_node_probe_latency_seconds Duration of the version handshake probe.")
+	fmt.Fprintln(r.w, "# TYPE siacoin_node_probe_latency_seconds gauge")
+	for _, res := range r.results {
+		if res.Err != nil {
+			continue
+		}
+		fmt.Fprintf(r.w, "siacoin_node_probe_latency_seconds{addr=%q,version=%q} %f\n", res.Address, res.Version, res.Latency)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+// Package report renders the outcome of probing nodes for their version
+// in a selectable output format, replacing the ad-hoc fmt.Printf calls
+// that used to be the only option.
+package report
+
+import (
+	"fmt"
+	"io"
+)
+
+// Reporter receives the outcome of probing a single node and renders it
+// in whatever format it implements. Record may be called concurrently
+// from multiple goroutines; implementations are responsible for their
+// own synchronization. Flush finalizes output (e.g. writing a closing
+// footer, or committing a textfile-collector file) and must be called
+// exactly once, after every Record call has returned.
+type Reporter interface {
+	Record(result Result)
+	Flush() error
+}
+
+// Result describes the outcome of a single probe.
+type Result struct {
+	Address string
+	Version string
+	Err     error
+	Latency float64 // seconds
+}
+
+// New constructs the Reporter named by format, writing to w.
+func New(format string, w io.Writer) (Reporter, error) {
+	switch format {
+	case "", "text":
+		return &textReporter{w: w}, nil
+	case "json":
+		return &jsonReporter{w: w}, nil
+	case "csv":
+		return newCSVReporter(w), nil
+	case "prom":
+		return &promReporter{w: w}, nil
+	default:
+		return nil, fmt.Errorf("report: unknown format %q", format)
+	}
+}
@@ -0,0 +1,117 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewUnknownFormat(t *testing.T) {
+	if _, err := New("yaml", &bytes.Buffer{}); err == nil {
+		t.Fatal("New() expected an error for an unknown format, got nil")
+	}
+}
+
+func TestTextReporterSkipsFailures(t *testing.T) {
+	var buf bytes.Buffer
+	r, err := New("text", &buf)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	r.Record(Result{Address: "1.1.1.1:9981", Version: "1.2.0"})
+	r.Record(Result{Address: "2.2.2.2:9981", Err: errors.New("dial failed")})
+	if err := r.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	want := "1.1.1.1:9981 -> 1.2.0\n"
+	if buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestJSONReporterRecordsReachabilityAndErrors(t *testing.T) {
+	var buf bytes.Buffer
+	r, err := New("json", &buf)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	r.Record(Result{Address: "1.1.1.1:9981", Version: "1.2.0", Latency: 0.25})
+	r.Record(Result{Address: "2.2.2.2:9981", Err: errors.New("timeout")})
+	if err := r.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+
+	var reachable jsonEntry
+	if err := json.Unmarshal([]byte(lines[0]), &reachable); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !reachable.Reachable || reachable.Version != "1.2.0" {
+		t.Errorf("first entry = %+v, want reachable with version 1.2.0", reachable)
+	}
+
+	var unreachable jsonEntry
+	if err := json.Unmarshal([]byte(lines[1]), &unreachable); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if unreachable.Reachable || unreachable.Error != "timeout" {
+		t.Errorf("second entry = %+v, want unreachable with error %q", unreachable, "timeout")
+	}
+}
+
+func TestCSVReporterWritesHeaderAndRows(t *testing.T) {
+	var buf bytes.Buffer
+	r, err := New("csv", &buf)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	r.Record(Result{Address: "1.1.1.1:9981", Version: "1.2.0", Latency: 0.1})
+	if err := r.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want header + 1 row", len(lines))
+	}
+	if lines[0] != "address,version,reachable,error,latency_seconds" {
+		t.Errorf("header = %q", lines[0])
+	}
+	if lines[1] != "1.1.1.1:9981,1.2.0,true,,0.100000" {
+		t.Errorf("row = %q", lines[1])
+	}
+}
+
+func TestPromReporterFormatsGauges(t *testing.T) {
+	var buf bytes.Buffer
+	r, err := New("prom", &buf)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	r.Record(Result{Address: "1.1.1.1:9981", Version: "1.2.0", Latency: 0.5})
+	r.Record(Result{Address: "2.2.2.2:9981", Err: errors.New("boom")})
+	if err := r.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, `siacoin_node_reachable{addr="1.1.1.1:9981",version="1.2.0"} 1`) {
+		t.Errorf("missing reachable gauge for the reachable node: %s", out)
+	}
+	if !strings.Contains(out, `siacoin_node_reachable{addr="2.2.2.2:9981",version=""} 0`) {
+		t.Errorf("missing reachable gauge for the unreachable node: %s", out)
+	}
+	if !strings.Contains(out, `siacoin_node_probe_latency_seconds{addr="1.1.1.1:9981",version="1.2.0"} 0.500000`) {
+		t.Errorf("missing latency gauge for the reachable node: %s", out)
+	}
+	if strings.Contains(out, `siacoin_node_probe_latency_seconds{addr="2.2.2.2:9981"`) {
+		t.Errorf("latency gauge emitted for an unreachable node: %s", out)
+	}
+}
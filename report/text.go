@@ -0,0 +1,27 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// textReporter preserves the tool's original human-readable output: one
+// "addr -> version" line per reachable node, silently skipping failures.
+type textReporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (r *textReporter) Record(res Result) {
+	if res.Err != nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fmt.Fprintf(r.w, "%v -> %v\n", res.Address, res.Version)
+}
+
+func (r *textReporter) Flush() error {
+	return nil
+}
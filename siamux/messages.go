@@ -0,0 +1,143 @@
+package siamux
+
+import "fmt"
+
+// Message codes understood by siamux participants.
+const (
+	CodeVersion           uint64 = iota // version handshake
+	CodeShareNodesRequest               // request for known peer addresses
+	CodeShareNodesResponse              // response carrying peer addresses
+	CodePing                            // liveness check
+	CodePong                            // reply to CodePing
+)
+
+// versionPayload is the JSON payload carried by a CodeVersion message.
+type versionPayload struct {
+	Version string `json:"version"`
+	// ListenAddr is the host:port the sender itself accepts inbound
+	// connections on, if any. It lets the receiving end record (and
+	// re-share via ShareNodes) an address that peers can actually dial,
+	// rather than the ephemeral source port of an outbound TCP
+	// connection. Left empty by a sender that doesn't run a listener,
+	// e.g. a one-shot crawl.
+	ListenAddr string `json:"listenAddr,omitempty"`
+}
+
+// HandshakeInfo is what each side of a version exchange learns about
+// the other.
+type HandshakeInfo struct {
+	Version    string
+	ListenAddr string
+}
+
+// shareNodesResponsePayload is the JSON payload carried by a
+// CodeShareNodesResponse message.
+type shareNodesResponsePayload struct {
+	Addresses []string `json:"addresses"`
+}
+
+// Handshake performs the version exchange: it sends ourVersion and
+// ourListenAddr (empty if we don't run a listener) and returns whatever
+// the remote end reports about itself.
+func (c *Conn) Handshake(ourVersion, ourListenAddr string) (HandshakeInfo, error) {
+	if err := c.WriteJSON(CodeVersion, versionPayload{Version: ourVersion, ListenAddr: ourListenAddr}); err != nil {
+		return HandshakeInfo{}, err
+	}
+
+	msg, err := c.ReadMsg()
+	if err != nil {
+		return HandshakeInfo{}, err
+	}
+	if msg.Code != CodeVersion {
+		return HandshakeInfo{}, fmt.Errorf("siamux: expected version message, got code %d", msg.Code)
+	}
+
+	var p versionPayload
+	if err := msg.Decode(&p); err != nil {
+		return HandshakeInfo{}, err
+	}
+	return HandshakeInfo{Version: p.Version, ListenAddr: p.ListenAddr}, nil
+}
+
+// ServeHandshake is the server-side counterpart to Handshake: it waits
+// for the remote end's version message and then replies with
+// ourVersion and ourListenAddr.
+func (c *Conn) ServeHandshake(ourVersion, ourListenAddr string) (HandshakeInfo, error) {
+	msg, err := c.ReadMsg()
+	if err != nil {
+		return HandshakeInfo{}, err
+	}
+	if msg.Code != CodeVersion {
+		return HandshakeInfo{}, fmt.Errorf("siamux: expected version message, got code %d", msg.Code)
+	}
+
+	var p versionPayload
+	if err := msg.Decode(&p); err != nil {
+		return HandshakeInfo{}, err
+	}
+
+	if err := c.WriteJSON(CodeVersion, versionPayload{Version: ourVersion, ListenAddr: ourListenAddr}); err != nil {
+		return HandshakeInfo{}, err
+	}
+	return HandshakeInfo{Version: p.Version, ListenAddr: p.ListenAddr}, nil
+}
+
+// RequestShareNodes asks the remote end to share the addresses it knows
+// about and returns them.
+func (c *Conn) RequestShareNodes() ([]string, error) {
+	if err := c.WriteMsg(CodeShareNodesRequest, nil); err != nil {
+		return nil, err
+	}
+
+	msg, err := c.ReadMsg()
+	if err != nil {
+		return nil, err
+	}
+	if msg.Code != CodeShareNodesResponse {
+		return nil, fmt.Errorf("siamux: expected ShareNodes response, got code %d", msg.Code)
+	}
+
+	var p shareNodesResponsePayload
+	if err := msg.Decode(&p); err != nil {
+		return nil, err
+	}
+	return p.Addresses, nil
+}
+
+// ReadShareNodesRequest blocks until it reads a CodeShareNodesRequest
+// message, discarding its (empty) payload.
+func (c *Conn) ReadShareNodesRequest() error {
+	msg, err := c.ReadMsg()
+	if err != nil {
+		return err
+	}
+	if msg.Code != CodeShareNodesRequest {
+		return fmt.Errorf("siamux: expected ShareNodes request, got code %d", msg.Code)
+	}
+	return nil
+}
+
+// WriteShareNodesResponse replies to a ShareNodes request with addrs.
+func (c *Conn) WriteShareNodesResponse(addrs []string) error {
+	return c.WriteJSON(CodeShareNodesResponse, shareNodesResponsePayload{Addresses: addrs})
+}
+
+// Ping sends a liveness check and waits for the matching pong.
+func (c *Conn) Ping() error {
+	if err := c.WriteMsg(CodePing, nil); err != nil {
+		return err
+	}
+	msg, err := c.ReadMsg()
+	if err != nil {
+		return err
+	}
+	if msg.Code != CodePong {
+		return fmt.Errorf("siamux: expected pong, got code %d", msg.Code)
+	}
+	return nil
+}
+
+// WritePong replies to a ping.
+func (c *Conn) WritePong() error {
+	return c.WriteMsg(CodePong, nil)
+}
@@ -0,0 +1,106 @@
+package siamux
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestReadWriteMsgRoundTrip(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	cc := NewConn(client)
+	sc := NewConn(server)
+
+	want := []byte("hello peer")
+	go func() {
+		if err := cc.WriteMsg(CodePing, want); err != nil {
+			t.Errorf("WriteMsg() error = %v", err)
+		}
+	}()
+
+	msg, err := sc.ReadMsg()
+	if err != nil {
+		t.Fatalf("ReadMsg() error = %v", err)
+	}
+	if msg.Code != CodePing {
+		t.Errorf("Code = %d, want %d", msg.Code, CodePing)
+	}
+	if msg.Size != uint64(len(want)) {
+		t.Errorf("Size = %d, want %d", msg.Size, len(want))
+	}
+
+	got, err := io.ReadAll(msg.Payload)
+	if err != nil {
+		t.Fatalf("reading payload: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("Payload = %q, want %q", got, want)
+	}
+}
+
+func TestReadMsgHandlesShortReads(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	go func() {
+		NewConn(client).WriteMsg(CodePong, []byte("ok"))
+	}()
+
+	// Wrap the server side so every underlying Read only returns a
+	// single byte, forcing ReadMsg's io.ReadFull calls to loop.
+	msg, err := NewConn(&trickleConn{Conn: server}).ReadMsg()
+	if err != nil {
+		t.Fatalf("ReadMsg() error = %v", err)
+	}
+	if msg.Code != CodePong {
+		t.Errorf("Code = %d, want %d", msg.Code, CodePong)
+	}
+	got, _ := io.ReadAll(msg.Payload)
+	if string(got) != "ok" {
+		t.Errorf("Payload = %q, want %q", got, "ok")
+	}
+}
+
+// trickleConn forces every Read to return at most one byte.
+type trickleConn struct {
+	net.Conn
+}
+
+func (c *trickleConn) Read(p []byte) (int, error) {
+	if len(p) > 1 {
+		p = p[:1]
+	}
+	return c.Conn.Read(p)
+}
+
+func TestReadMsgRejectsOversizedPayload(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	go func() {
+		header := make([]byte, 16)
+		header[8] = 0xff // size bytes, little-endian: absurdly large
+		header[9] = 0xff
+		header[10] = 0xff
+		header[11] = 0xff
+		header[12] = 0xff
+		header[13] = 0xff
+		header[14] = 0xff
+		header[15] = 0xff
+		client.Write(header)
+	}()
+
+	client.SetDeadline(time.Now().Add(5 * time.Second))
+	server.SetDeadline(time.Now().Add(5 * time.Second))
+
+	_, err := NewConn(server).ReadMsg()
+	if err == nil {
+		t.Fatal("ReadMsg() expected an error for an oversized payload, got nil")
+	}
+}